@@ -1,8 +1,13 @@
 package server
 
 import (
+	"encoding/json"
 	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
 	"strings"
+	"time"
 
 	"github.com/spf13/cast"
 	"github.com/spf13/cobra"
@@ -10,14 +15,80 @@ import (
 	"github.com/cosmos/cosmos-sdk/client/flags"
 	"github.com/cosmos/cosmos-sdk/server/types"
 	"github.com/cosmos/cosmos-sdk/store"
-	"github.com/cosmos/cosmos-sdk/store/rootmulti"
 	storetypes "github.com/cosmos/cosmos-sdk/store/types"
 )
 
 const (
 	FlagDBType = "app-db-backend"
+
+	// FlagPruningWorkers bounds how many substores the offline pruning
+	// command prunes concurrently for a given height.
+	FlagPruningWorkers = "pruning-workers"
+
+	// FlagDryRun reports what the offline pruning command would prune
+	// without mutating the underlying DB.
+	FlagDryRun = "dry-run"
+
+	// pruningCheckpointFile records the last successfully-pruned height
+	// under the node's home directory so an interrupted `prune` run can
+	// resume instead of rescanning from the start.
+	pruningCheckpointFile = "prune_checkpoint.json"
+
+	// pruningStrategyTime keeps a wall-clock window of history, e.g.
+	// "--pruning=time --pruning-keep-duration=720h" keeps the last 30 days.
+	pruningStrategyTime = "time"
+
+	// FlagPruningKeepDuration is the retention window consulted when
+	// --pruning=time, expressed as a Go duration (e.g. "720h").
+	FlagPruningKeepDuration = "pruning-keep-duration"
 )
 
+// pruningCheckpoint is the on-disk representation of pruningCheckpointFile.
+type pruningCheckpoint struct {
+	LastPrunedHeight int64 `json:"last_pruned_height"`
+}
+
+// loadPruningCheckpoint reads the last successfully-pruned height recorded
+// under home, returning 0 if no checkpoint has been written yet.
+func loadPruningCheckpoint(home string) (int64, error) {
+	bz, err := os.ReadFile(filepath.Join(home, pruningCheckpointFile))
+	switch {
+	case os.IsNotExist(err):
+		return 0, nil
+	case err != nil:
+		return 0, err
+	}
+
+	var checkpoint pruningCheckpoint
+	if err := json.Unmarshal(bz, &checkpoint); err != nil {
+		return 0, fmt.Errorf("failed to parse pruning checkpoint: %w", err)
+	}
+
+	return checkpoint.LastPrunedHeight, nil
+}
+
+// savePruningCheckpoint records height as the last successfully-pruned
+// version so a subsequent `prune` invocation can resume from there.
+func savePruningCheckpoint(home string, height int64) error {
+	bz, err := json.Marshal(pruningCheckpoint{LastPrunedHeight: height})
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(filepath.Join(home, pruningCheckpointFile), bz, 0o600)
+}
+
+// clearPruningCheckpoint removes the checkpoint file once a pruning run has
+// processed every height, so the next invocation starts a fresh pass.
+func clearPruningCheckpoint(home string) error {
+	err := os.Remove(filepath.Join(home, pruningCheckpointFile))
+	if os.IsNotExist(err) {
+		return nil
+	}
+
+	return err
+}
+
 // GetPruningOptionsFromFlags parses command flags and returns the correct
 // PruningOptions. If a pruning strategy is provided, that will be parsed and
 // returned, otherwise, it is assumed custom pruning options are provided.
@@ -41,11 +112,76 @@ func GetPruningOptionsFromFlags(appOpts types.AppOptions) (storetypes.PruningOpt
 
 		return opts, nil
 
+	case pruningStrategyTime:
+		keepDuration := cast.ToDuration(appOpts.Get(FlagPruningKeepDuration))
+		if keepDuration <= 0 {
+			return store.PruningOptions{}, fmt.Errorf("--%s must be a positive duration when --pruning=time", FlagPruningKeepDuration)
+		}
+
+		// The concrete version range depends on the store's block-time
+		// index, which isn't available here; it is resolved once the store
+		// is opened, by resolveTimeBasedPruning.
+		return store.PruningOptions{}, nil
+
 	default:
 		return store.PruningOptions{}, fmt.Errorf("unknown pruning strategy %s", strategy)
 	}
 }
 
+// blockTimeIndex is implemented by store backends that can answer "what was
+// committed at version v, and when". It is consulted by
+// resolveTimeBasedPruning to translate a wall-clock retention window into a
+// concrete version range for the `time` pruning strategy. It is kept
+// independent of storetypes.CommitMultiStore so resolveTimeBasedPruning can
+// be unit tested with a fake implementation.
+type blockTimeIndex interface {
+	LatestVersion() int64
+	GetCommitInfo(version int64) (*storetypes.CommitInfo, error)
+}
+
+// resolveTimeBasedPruning translates keepDuration into a PruningOptions that
+// retains every version committed within the last keepDuration. Block times
+// are assumed to be monotonically non-decreasing with height, as they are
+// on a BFT chain, so the cutoff height is located with a binary search over
+// indexer rather than a linear scan of every historical height, which would
+// cost one DB read per retained version on a long retention window.
+func resolveTimeBasedPruning(indexer blockTimeIndex, keepDuration time.Duration) (storetypes.PruningOptions, error) {
+	latest := indexer.LatestVersion()
+	if latest <= 0 {
+		return storetypes.PruningOptions{}, fmt.Errorf("no committed versions to prune")
+	}
+
+	cutoff := time.Now().Add(-keepDuration)
+
+	var lookupErr error
+	// oldestKept is the 0-indexed position of the first height (1-indexed as
+	// oldestKept+1) whose commit time falls within the retention window.
+	oldestKept := sort.Search(int(latest), func(i int) bool {
+		info, err := indexer.GetCommitInfo(int64(i) + 1)
+		if err != nil {
+			lookupErr = err
+			return true
+		}
+
+		return !info.Timestamp.Before(cutoff)
+	})
+	if lookupErr != nil {
+		return storetypes.PruningOptions{}, lookupErr
+	}
+
+	keepRecent := uint64(latest - int64(oldestKept))
+	if keepRecent == 0 {
+		return storetypes.PruningOptions{}, fmt.Errorf("--%s=%s would leave zero versions on disk", FlagPruningKeepDuration, keepDuration)
+	}
+
+	opts := storetypes.NewPruningOptions(keepRecent, 0, 1)
+	if err := opts.Validate(); err != nil {
+		return storetypes.PruningOptions{}, fmt.Errorf("invalid time-based pruning options: %w", err)
+	}
+
+	return opts, nil
+}
+
 // PruningCmd prunes the sdk root multi store history versions based on the pruning options
 // specified by command flags.
 func PruningCmd(providerCreator types.StoreProviderCreator) *cobra.Command {
@@ -54,13 +190,14 @@ func PruningCmd(providerCreator types.StoreProviderCreator) *cobra.Command {
 		Short: "prune history stetes based on the pruning options specified by flags",
 		Long: `Pruning options can be provided via the '--pruning' flag or alternatively with '--pruning-keep-recent', and
 		'pruning-interval' together.
-		
+
 		For '--pruning' the options are as follows:
-		
+
 		default: the last 362880 states are kept, pruning at 10 block intervals
 		nothing: all historic states will be saved, nothing will be deleted (i.e. archiving node)
 		everything: 2 latest states will be kept; pruning at 10 block intervals.
-		custom: allow pruning options to be manually specified through 'pruning-keep-recent', and 'pruning-interval'
+		custom: allow pruning options to be manually specified through 'pruning-keep-recent', 'pruning-keep-every', and 'pruning-interval'
+		time: keep a wall-clock window of history via '--pruning-keep-duration' (e.g. '--pruning-keep-duration=720h' keeps the last 30 days)
 		`,
 		PreRunE: func(cmd *cobra.Command, _ []string) error {
 			serverCtx := GetServerContextFromCmd(cmd)
@@ -78,8 +215,13 @@ func PruningCmd(providerCreator types.StoreProviderCreator) *cobra.Command {
 			ctx := GetServerContextFromCmd(cmd)
 
 			home := ctx.Viper.GetString(flags.FlagHome)
+			dryRun := ctx.Viper.GetBool(FlagDryRun)
+			workers := ctx.Viper.GetInt(FlagPruningWorkers)
+			if workers < 1 {
+				workers = 1
+			}
 
-			db, err := openDB(home)
+			db, err := openDB(home, GetAppDBBackend(ctx.Viper))
 			if err != nil {
 				return err
 			}
@@ -90,27 +232,93 @@ func PruningCmd(providerCreator types.StoreProviderCreator) *cobra.Command {
 			cmsOptions := cms.GetPruning()
 			// set pruning options for cms in case we forgot to apply the pruning options in providerCreator
 			if cmsOptions.Interval == 0 && cmsOptions.KeepRecent == 0 {
-				pruningOptions, err := GetPruningOptionsFromFlags(ctx.Viper)
+				var pruningOptions storetypes.PruningOptions
+				if strings.ToLower(ctx.Viper.GetString(FlagPruning)) == pruningStrategyTime {
+					indexer, ok := cms.(blockTimeIndex)
+					if !ok {
+						return fmt.Errorf("the committed multistore of type %T does not expose a block-time index required by --pruning=time", cms)
+					}
+					pruningOptions, err = resolveTimeBasedPruning(indexer, ctx.Viper.GetDuration(FlagPruningKeepDuration))
+				} else {
+					pruningOptions, err = GetPruningOptionsFromFlags(ctx.Viper)
+				}
 				if err != nil {
 					return err
 				}
 				cms.SetPruning(pruningOptions)
 			}
 
-			if rootMultiStore, ok := cms.(*rootmulti.Store); ok {
-				err = rootMultiStore.PruneHistoryVersions()
+			pruner, ok := cms.(storetypes.HistoricalPruner)
+			if !ok {
+				return fmt.Errorf("the committed multistore of type %T does not support offline pruning", cms)
+			}
+
+			startHeight, err := loadPruningCheckpoint(home)
+			if err != nil {
 				return err
 			}
+			if startHeight > 0 {
+				ctx.Logger.Info("resuming offline pruning from checkpoint", "height", startHeight)
+			}
+
+			began := time.Now()
+			var processed int64
+
+			pruneErr := pruner.PruneHistoryVersionsWithOptions(cmd.Context(), storetypes.PruneOptions{
+				Workers:     workers,
+				DryRun:      dryRun,
+				StartHeight: startHeight,
+				Progress: func(height, remaining int64) {
+					processed++
+
+					if !dryRun {
+						if err := savePruningCheckpoint(home, height); err != nil {
+							ctx.Logger.Error("failed to save pruning checkpoint", "height", height, "err", err)
+						}
+					}
+
+					// avoid flooding the logs; report every 100 versions and on the last one
+					if processed%100 != 0 && remaining != 0 {
+						return
+					}
+
+					elapsed := time.Since(began)
+					var eta time.Duration
+					if rate := float64(processed) / elapsed.Seconds(); rate > 0 {
+						eta = time.Duration(float64(remaining)/rate) * time.Second
+					}
+
+					ctx.Logger.Info(
+						"pruning progress",
+						"height", height,
+						"processed", processed,
+						"remaining", remaining,
+						"eta", eta.Round(time.Second),
+					)
+				},
+			})
+			if pruneErr != nil {
+				return pruneErr
+			}
+
+			if dryRun {
+				ctx.Logger.Info("dry run complete, no data was pruned", "versions", processed)
+				return nil
+			}
 
-			return fmt.Errorf("currently only support the pruning of rootmulti.Store type")
+			return clearPruningCheckpoint(home)
 		},
 	}
 
 	cmd.Flags().String(flags.FlagHome, "", "The database home directory")
-	cmd.Flags().String(FlagPruning, storetypes.PruningOptionDefault, "Pruning strategy (default|nothing|everything|custom)")
+	cmd.Flags().String(FlagPruning, storetypes.PruningOptionDefault, "Pruning strategy (default|nothing|everything|custom|time)")
 	cmd.Flags().Uint64(FlagPruningKeepRecent, 0, "Number of recent heights to keep on disk (ignored if pruning is not 'custom')")
+	cmd.Flags().Uint64(FlagPruningKeepEvery, 0, "Height interval at which a state is kept on disk, 0 keeps none aside from pruning-keep-recent (ignored if pruning is not 'custom')")
 	cmd.Flags().Uint64(FlagPruningInterval, 0, "Height interval at which pruned heights are removed from disk (ignored if pruning is not 'custom')")
 	cmd.Flags().String(FlagDBType, "", "the backend db type")
+	cmd.Flags().Int(FlagPruningWorkers, 1, "Number of workers used to prune substores concurrently")
+	cmd.Flags().Bool(FlagDryRun, false, "Report the versions that would be pruned without mutating the database")
+	cmd.Flags().Duration(FlagPruningKeepDuration, 0, "Wall-clock retention window to keep on disk (ignored unless pruning is 'time')")
 
 	return cmd
 }
@@ -0,0 +1,89 @@
+package server
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	storetypes "github.com/cosmos/cosmos-sdk/store/types"
+)
+
+func TestPruningCheckpointRoundTrip(t *testing.T) {
+	home := t.TempDir()
+
+	height, err := loadPruningCheckpoint(home)
+	if err != nil {
+		t.Fatalf("loadPruningCheckpoint: %v", err)
+	}
+	if height != 0 {
+		t.Fatalf("expected no checkpoint yet, got height %d", height)
+	}
+
+	if err := savePruningCheckpoint(home, 42); err != nil {
+		t.Fatalf("savePruningCheckpoint: %v", err)
+	}
+
+	height, err = loadPruningCheckpoint(home)
+	if err != nil {
+		t.Fatalf("loadPruningCheckpoint: %v", err)
+	}
+	if height != 42 {
+		t.Fatalf("expected height 42, got %d", height)
+	}
+
+	if err := clearPruningCheckpoint(home); err != nil {
+		t.Fatalf("clearPruningCheckpoint: %v", err)
+	}
+
+	if _, err := os.Stat(filepath.Join(home, pruningCheckpointFile)); !os.IsNotExist(err) {
+		t.Fatalf("expected the checkpoint file to be removed, stat err = %v", err)
+	}
+}
+
+func TestClearPruningCheckpointMissingIsNoop(t *testing.T) {
+	if err := clearPruningCheckpoint(t.TempDir()); err != nil {
+		t.Fatalf("expected no error clearing a checkpoint that was never written, got %v", err)
+	}
+}
+
+// fakeBlockTimeIndex commits height h at now - (latest-h) hours, i.e. the
+// latest height is committed "now" and earlier heights recede into the past
+// by one simulated hour each.
+type fakeBlockTimeIndex struct {
+	latest int64
+	now    time.Time
+}
+
+func (f fakeBlockTimeIndex) LatestVersion() int64 { return f.latest }
+
+func (f fakeBlockTimeIndex) GetCommitInfo(version int64) (*storetypes.CommitInfo, error) {
+	age := time.Duration(f.latest-version) * time.Hour
+	return &storetypes.CommitInfo{Version: version, Timestamp: f.now.Add(-age)}, nil
+}
+
+func TestResolveTimeBasedPruning(t *testing.T) {
+	indexer := fakeBlockTimeIndex{latest: 100, now: time.Now()}
+
+	// use a 30m cushion past the 24h boundary between heights 75 and 76 so
+	// the real wall-clock elapsed between capturing "now" above and the
+	// cutoff computed inside resolveTimeBasedPruning can't flip the result.
+	opts, err := resolveTimeBasedPruning(indexer, 24*time.Hour+30*time.Minute)
+	if err != nil {
+		t.Fatalf("resolveTimeBasedPruning: %v", err)
+	}
+
+	// heights 76..100 were committed within the last 24h30m.
+	if opts.KeepRecent != 25 {
+		t.Fatalf("expected KeepRecent=25, got %d", opts.KeepRecent)
+	}
+}
+
+func TestResolveTimeBasedPruningRejectsZeroVersions(t *testing.T) {
+	// a single, ancient version: even the latest falls outside the window.
+	indexer := fakeBlockTimeIndex{latest: 1, now: time.Now().Add(-400 * 24 * time.Hour)}
+
+	if _, err := resolveTimeBasedPruning(indexer, time.Hour); err == nil {
+		t.Fatal("expected an error when the retention window would leave zero versions on disk")
+	}
+}
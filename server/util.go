@@ -0,0 +1,26 @@
+package server
+
+import (
+	"path/filepath"
+
+	dbm "github.com/cometbft/cometbft-db"
+	"github.com/spf13/cast"
+
+	"github.com/cosmos/cosmos-sdk/server/types"
+)
+
+// GetAppDBBackend gets the backend type to use for the application DBs.
+func GetAppDBBackend(opts types.AppOptions) dbm.BackendType {
+	if backend := cast.ToString(opts.Get(FlagDBType)); len(backend) != 0 {
+		return dbm.BackendType(backend)
+	}
+
+	return dbm.GoLevelDBBackend
+}
+
+// openDB opens the application database under rootDir using the given
+// backend type.
+func openDB(rootDir string, backendType dbm.BackendType) (dbm.DB, error) {
+	dataDir := filepath.Join(rootDir, "data")
+	return dbm.NewDB("application", backendType, dataDir)
+}
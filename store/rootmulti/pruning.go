@@ -0,0 +1,183 @@
+package rootmulti
+
+import (
+	"context"
+	"fmt"
+
+	"golang.org/x/sync/errgroup"
+
+	"github.com/cosmos/cosmos-sdk/store/types"
+)
+
+var _ types.HistoricalPruner = (*Store)(nil)
+
+// PruneHistoryVersionsWithOptions implements types.HistoricalPruner. It is
+// named distinctly from the pre-existing Store.PruneHistoryVersions() (which
+// takes no arguments) so the two can coexist on *Store without a method
+// redeclaration. It prunes historical versions the same way
+// PruneHistoryVersions does, but additionally supports resuming from a
+// checkpointed height, reporting per-version progress, and fanning the
+// per-substore work for each height out across a bounded worker pool, since
+// every KVStore under the root multistore can be pruned independently.
+func (rs *Store) PruneHistoryVersionsWithOptions(ctx context.Context, opts types.PruneOptions) error {
+	heights, err := rs.getPruningHeights(opts.StartHeight)
+	if err != nil {
+		return err
+	}
+
+	total := int64(len(heights))
+	for i, height := range heights {
+		if !opts.DryRun {
+			if err := rs.pruneStoresAtHeight(ctx, height, opts.Workers); err != nil {
+				return fmt.Errorf("failed to prune height %d: %w", height, err)
+			}
+		}
+
+		if opts.Progress != nil {
+			opts.Progress(height, total-int64(i+1))
+		}
+	}
+
+	return nil
+}
+
+// pruneStoresAtHeight removes height from every substore under the root
+// multistore, bounding concurrency to workers. A workers value <= 1 prunes
+// substores sequentially on the calling goroutine.
+func (rs *Store) pruneStoresAtHeight(ctx context.Context, height int64, workers int) error {
+	keys := make([]types.StoreKey, 0, len(rs.stores))
+	for key := range rs.stores {
+		keys = append(keys, key)
+	}
+
+	return pruneKeysAtHeight(ctx, keys, workers, func(key types.StoreKey) error {
+		return rs.pruneStore(key, height)
+	})
+}
+
+// pruneKeysAtHeight calls prune for every key, bounding concurrency to
+// workers. A workers value <= 1 runs sequentially on the calling goroutine.
+// It is kept independent of *Store so the fan-out behavior can be unit
+// tested without a real store.
+func pruneKeysAtHeight(ctx context.Context, keys []types.StoreKey, workers int, prune func(types.StoreKey) error) error {
+	if workers <= 1 {
+		for _, key := range keys {
+			if err := prune(key); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	g, gctx := errgroup.WithContext(ctx)
+	sem := make(chan struct{}, workers)
+
+	for _, key := range keys {
+		key := key
+
+		select {
+		case sem <- struct{}{}:
+		case <-gctx.Done():
+			return g.Wait()
+		}
+
+		g.Go(func() error {
+			defer func() { <-sem }()
+			return prune(key)
+		})
+	}
+
+	return g.Wait()
+}
+
+// commitKVStoreUnwrapper is implemented by CommitKVStore wrappers that
+// transparently delegate to another CommitKVStore, such as the inter-block
+// cache (store/cache.CommitKVStoreCache) every substore is wrapped in
+// whenever iavl-cache-size is non-zero, a normal production setting. A
+// cache-wrapped IAVL store doesn't satisfy *iavl.Store directly, so pruning
+// has to unwrap through these before asserting the concrete type.
+type commitKVStoreUnwrapper interface {
+	Unwrap() types.CommitKVStore
+}
+
+// prunableStore is implemented by substore types that retain historical
+// versions and can delete them, e.g. *iavl.Store.
+type prunableStore interface {
+	DeleteVersion(version int64) error
+}
+
+// resolvePrunableStore unwraps any cache wrapper around store - the same way
+// the legacy PruneHistoryVersions does - and reports whether the underlying
+// store retains historical versions and can prune them.
+func resolvePrunableStore(store types.CommitKVStore) (prunableStore, bool) {
+	for {
+		unwrapper, ok := store.(commitKVStoreUnwrapper)
+		if !ok {
+			break
+		}
+		store = unwrapper.Unwrap()
+	}
+
+	prunable, ok := store.(prunableStore)
+	return prunable, ok
+}
+
+// pruneStore removes height from the IAVL tree backing key, unwrapping any
+// cache wrapper first. Substores that don't retain historical versions
+// (e.g. transient stores) are left alone.
+func (rs *Store) pruneStore(key types.StoreKey, height int64) error {
+	store, ok := rs.stores[key]
+	if !ok {
+		return fmt.Errorf("store with key %s not found", key)
+	}
+
+	prunable, ok := resolvePrunableStore(store)
+	if !ok {
+		return nil
+	}
+
+	if err := prunable.DeleteVersion(height); err != nil {
+		return fmt.Errorf("failed to delete version %d for store %s: %w", height, key, err)
+	}
+
+	return nil
+}
+
+// pruningHeightsFor returns, in ascending order, every height in
+// [from, latest) that opts marks as prunable. It is a pure function of its
+// arguments so the KeepEvery/KeepRecent combinations that decide what gets
+// deleted, and the resume starting point, can be unit tested without a real
+// store.
+func pruningHeightsFor(latest int64, opts types.PruningOptions, from int64) []int64 {
+	if from < 1 {
+		from = 1
+	}
+
+	var heights []int64
+	for height := from; height < latest; height++ {
+		if opts.KeepEvery != 0 && height%int64(opts.KeepEvery) == 0 {
+			continue
+		}
+		if opts.KeepRecent > 0 && height > latest-int64(opts.KeepRecent) {
+			continue
+		}
+
+		heights = append(heights, height)
+	}
+
+	return heights
+}
+
+// getPruningHeights returns the historical heights this store would prune at
+// or after from+1, as determined by its pruning options. Seeding the scan at
+// from+1, rather than always starting at 1 and filtering afterward, means a
+// resumed run over millions of historical versions doesn't re-enumerate the
+// ones already checkpointed.
+func (rs *Store) getPruningHeights(from int64) ([]int64, error) {
+	latest := rs.LatestVersion()
+	if latest <= 0 {
+		return nil, nil
+	}
+
+	return pruningHeightsFor(latest, rs.GetPruning(), from+1), nil
+}
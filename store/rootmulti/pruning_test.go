@@ -0,0 +1,191 @@
+package rootmulti
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"github.com/cosmos/cosmos-sdk/store/types"
+)
+
+type fakeStoreKey string
+
+func (k fakeStoreKey) Name() string   { return string(k) }
+func (k fakeStoreKey) String() string { return string(k) }
+
+// fakeCommitKVStore is a leaf CommitKVStore that does not retain historical
+// versions (e.g. a transient store), used to exercise resolvePrunableStore's
+// negative case. It embeds the interface so the zero value structurally
+// satisfies types.CommitKVStore without implementing every method by hand;
+// none of the embedded methods are exercised in these tests.
+type fakeCommitKVStore struct {
+	types.CommitKVStore
+}
+
+// fakeCacheWrapper stands in for the inter-block cache: a CommitKVStore that
+// transparently delegates to another one via Unwrap.
+type fakeCacheWrapper struct {
+	types.CommitKVStore
+	underlying types.CommitKVStore
+}
+
+func (f fakeCacheWrapper) Unwrap() types.CommitKVStore { return f.underlying }
+
+// fakePrunableStore stands in for *iavl.Store: a leaf CommitKVStore that
+// retains historical versions and records which ones get deleted.
+type fakePrunableStore struct {
+	types.CommitKVStore
+	deleted []int64
+}
+
+func (f *fakePrunableStore) DeleteVersion(version int64) error {
+	f.deleted = append(f.deleted, version)
+	return nil
+}
+
+func TestResolvePrunableStoreUnwrapsCacheWrapper(t *testing.T) {
+	leaf := &fakePrunableStore{}
+	wrapped := fakeCacheWrapper{underlying: leaf}
+	doubleWrapped := fakeCacheWrapper{underlying: wrapped}
+
+	prunable, ok := resolvePrunableStore(doubleWrapped)
+	if !ok {
+		t.Fatal("expected a cache-wrapped prunable store to be found after unwrapping")
+	}
+
+	if err := prunable.DeleteVersion(7); err != nil {
+		t.Fatalf("DeleteVersion: %v", err)
+	}
+	if len(leaf.deleted) != 1 || leaf.deleted[0] != 7 {
+		t.Fatalf("expected the underlying store to receive DeleteVersion(7), got %v", leaf.deleted)
+	}
+}
+
+func TestResolvePrunableStoreNonPrunableLeaf(t *testing.T) {
+	wrapped := fakeCacheWrapper{underlying: fakeCommitKVStore{}}
+
+	if _, ok := resolvePrunableStore(wrapped); ok {
+		t.Fatal("expected a non-prunable leaf store to not be treated as prunable")
+	}
+}
+
+func TestPruningHeightsFor(t *testing.T) {
+	cases := map[string]struct {
+		latest   int64
+		opts     types.PruningOptions
+		from     int64
+		expected []int64
+	}{
+		"keep recent only": {
+			latest:   10,
+			opts:     types.PruningOptions{KeepRecent: 3},
+			expected: []int64{1, 2, 3, 4, 5, 6, 7},
+		},
+		"height == latest-KeepRecent is still prunable": {
+			latest:   10,
+			opts:     types.PruningOptions{KeepRecent: 4},
+			expected: []int64{1, 2, 3, 4, 5, 6},
+		},
+		"keep every combined with keep recent": {
+			latest:   10,
+			opts:     types.PruningOptions{KeepEvery: 3, KeepRecent: 2},
+			expected: []int64{1, 2, 4, 5, 7, 8},
+		},
+		"keep recent zero prunes everything below latest": {
+			latest:   5,
+			opts:     types.PruningOptions{KeepRecent: 0},
+			expected: []int64{1, 2, 3, 4},
+		},
+		"resumes from a checkpoint instead of re-enumerating from 1": {
+			latest:   10,
+			opts:     types.PruningOptions{KeepRecent: 3},
+			from:     5,
+			expected: []int64{5, 6, 7},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			got := pruningHeightsFor(tc.latest, tc.opts, tc.from)
+			if len(got) != len(tc.expected) {
+				t.Fatalf("expected %v, got %v", tc.expected, got)
+			}
+			for i := range got {
+				if got[i] != tc.expected[i] {
+					t.Fatalf("expected %v, got %v", tc.expected, got)
+				}
+			}
+		})
+	}
+}
+
+func TestPruneKeysAtHeightSequential(t *testing.T) {
+	keys := []types.StoreKey{fakeStoreKey("a"), fakeStoreKey("b"), fakeStoreKey("c")}
+
+	var pruned []types.StoreKey
+	err := pruneKeysAtHeight(context.Background(), keys, 1, func(key types.StoreKey) error {
+		pruned = append(pruned, key)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("pruneKeysAtHeight: %v", err)
+	}
+	if len(pruned) != len(keys) {
+		t.Fatalf("expected every key to be pruned, got %v", pruned)
+	}
+}
+
+func TestPruneKeysAtHeightConcurrentBoundsWorkers(t *testing.T) {
+	keys := make([]types.StoreKey, 0, 10)
+	for i := 0; i < 10; i++ {
+		keys = append(keys, fakeStoreKey(string(rune('a'+i))))
+	}
+
+	var (
+		inFlight int32
+		maxSeen  int32
+		mu       sync.Mutex
+		pruned   = map[types.StoreKey]bool{}
+	)
+
+	err := pruneKeysAtHeight(context.Background(), keys, 3, func(key types.StoreKey) error {
+		n := atomic.AddInt32(&inFlight, 1)
+		defer atomic.AddInt32(&inFlight, -1)
+
+		for {
+			old := atomic.LoadInt32(&maxSeen)
+			if n <= old || atomic.CompareAndSwapInt32(&maxSeen, old, n) {
+				break
+			}
+		}
+
+		mu.Lock()
+		pruned[key] = true
+		mu.Unlock()
+
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("pruneKeysAtHeight: %v", err)
+	}
+	if len(pruned) != len(keys) {
+		t.Fatalf("expected every key to be pruned, got %d of %d", len(pruned), len(keys))
+	}
+	if maxSeen > 3 {
+		t.Fatalf("expected at most 3 concurrent prunes, saw %d", maxSeen)
+	}
+}
+
+func TestPruneKeysAtHeightPropagatesError(t *testing.T) {
+	keys := []types.StoreKey{fakeStoreKey("a"), fakeStoreKey("b")}
+	boom := errors.New("boom")
+
+	err := pruneKeysAtHeight(context.Background(), keys, 2, func(key types.StoreKey) error {
+		return boom
+	})
+	if !errors.Is(err, boom) {
+		t.Fatalf("expected the prune error to propagate, got %v", err)
+	}
+}
@@ -0,0 +1,39 @@
+package types
+
+import "context"
+
+// PruneOptions configures a single offline pruning run against a
+// HistoricalPruner, independent of any particular store backend.
+type PruneOptions struct {
+	// Workers bounds how many substores a backend may prune concurrently for
+	// a given height. Values <= 1 indicate sequential pruning.
+	Workers int
+
+	// DryRun reports the versions that would be pruned without mutating the
+	// underlying DB.
+	DryRun bool
+
+	// StartHeight resumes pruning from a previously checkpointed height
+	// instead of rescanning from the oldest prunable version.
+	StartHeight int64
+
+	// Progress, when set, is invoked once per version after it has been
+	// pruned (or would have been, in dry-run mode), reporting the height
+	// just processed and how many prunable versions remain.
+	Progress func(height, remaining int64)
+}
+
+// HistoricalPruner is implemented by commit multistore backends that can
+// prune historical versions outside of normal block execution, e.g. via the
+// offline `prune` CLI command. rootmulti.Store is the reference
+// implementation; alternative backends (an SMT-backed store, a future IAVL
+// v1 fastnode variant, etc.) can implement it to plug into the same
+// tooling.
+//
+// The method is named PruneHistoryVersionsWithOptions, rather than
+// PruneHistoryVersions, so it doesn't collide with the pre-existing
+// zero-argument Store.PruneHistoryVersions() method that backends such as
+// rootmulti.Store already expose.
+type HistoricalPruner interface {
+	PruneHistoryVersionsWithOptions(ctx context.Context, opts PruneOptions) error
+}